@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	CategoryBreaking = "breaking"
+	CategoryFeatures = "features"
+	CategoryFixes    = "fixes"
+	CategoryPerf     = "perf"
+	CategoryRefactor = "refactor"
+	CategoryDocs     = "docs"
+	CategoryChore    = "chore"
+	CategoryOther    = "other"
+)
+
+var categoryTitles = map[string]string{
+	CategoryBreaking: "Breaking Changes",
+	CategoryFeatures: "Features",
+	CategoryFixes:    "Bug Fixes",
+	CategoryPerf:     "Performance",
+	CategoryRefactor: "Refactors",
+	CategoryDocs:     "Documentation",
+	CategoryChore:    "Chores",
+	CategoryOther:    "Other",
+}
+
+// categoryOrder controls the order sections are rendered in.
+var categoryOrder = []string{
+	CategoryBreaking,
+	CategoryFeatures,
+	CategoryFixes,
+	CategoryPerf,
+	CategoryRefactor,
+	CategoryDocs,
+	CategoryChore,
+	CategoryOther,
+}
+
+var conventionalCommitRegex = regexp.MustCompile(`^([a-zA-Z]+)(\([^)]*\))?(!)?:\s*(.+)$`)
+
+var conventionalTypeToCategory = map[string]string{
+	"feat":     CategoryFeatures,
+	"fix":      CategoryFixes,
+	"perf":     CategoryPerf,
+	"refactor": CategoryRefactor,
+	"docs":     CategoryDocs,
+	"chore":    CategoryChore,
+}
+
+// categorize classifies a merge by its title's Conventional Commits prefix
+// (feat:, fix:, ...; a trailing ! or a "BREAKING CHANGE" marker wins as
+// breaking). If the title doesn't follow that convention, it falls back to
+// mapping the merge's labels through labelCategories.
+func categorize(m MergeInfo, labelCategories map[string]string) string {
+	if matches := conventionalCommitRegex.FindStringSubmatch(m.Title); matches != nil {
+		if matches[3] == "!" {
+			return CategoryBreaking
+		}
+		if cat, ok := conventionalTypeToCategory[strings.ToLower(matches[1])]; ok {
+			return cat
+		}
+	}
+
+	if strings.Contains(m.Title, "BREAKING CHANGE") {
+		return CategoryBreaking
+	}
+
+	for _, label := range m.Labels {
+		if cat, ok := labelCategories[label]; ok {
+			return cat
+		}
+	}
+
+	return CategoryOther
+}
+
+type labelsConfig struct {
+	Labels map[string]string `yaml:"labels"`
+}
+
+// loadLabelsConfig reads the -labels-config YAML file mapping forge label
+// names to categories (one of the Category* constants above). An empty path
+// disables label-based classification.
+func loadLabelsConfig(path string) (map[string]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read labels config: %w", err)
+	}
+
+	var cfg labelsConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("can't parse labels config: %w", err)
+	}
+
+	return cfg.Labels, nil
+}
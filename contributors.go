@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type Contributor struct {
+	UserName       string
+	UserProfileUrl string
+	MergeCount     int
+}
+
+// Markdown renders the contributor as a Markdown link, or just their name if
+// no profile URL is known (e.g. a co-author resolved from a commit trailer).
+func (c Contributor) Markdown() string {
+	if c.UserProfileUrl == "" {
+		return c.UserName
+	}
+	return fmt.Sprintf("[%s](%s)", c.UserName, c.UserProfileUrl)
+}
+
+// CoAuthorFetcher is implemented by forges that can resolve additional
+// contributors from commit trailers. Forges that don't implement it are
+// simply skipped when collecting co-authors.
+type CoAuthorFetcher interface {
+	FetchCoAuthors(mergeNum int) ([]string, error)
+}
+
+var coAuthoredByRegex = regexp.MustCompile(`(?m)^Co-authored-by:\s*([^<]+?)\s*<`)
+
+// parseCoAuthorTrailers extracts the names out of "Co-authored-by: Name <email>"
+// trailers in a commit message.
+func parseCoAuthorTrailers(message string) []string {
+	var names []string
+	for _, m := range coAuthoredByRegex.FindAllStringSubmatch(message, -1) {
+		names = append(names, strings.TrimSpace(m[1]))
+	}
+	return names
+}
+
+// collectContributors deduplicates merge authors by UserName, additionally
+// resolving co-authors via forge's CoAuthorFetcher (if implemented), and
+// returns them sorted by merge count descending.
+func collectContributors(forge Forge, merges []MergeInfo) ([]Contributor, error) {
+	counts := map[string]*Contributor{}
+	var order []string
+
+	add := func(name, profileUrl string) {
+		if name == "" {
+			return
+		}
+		c, ok := counts[name]
+		if !ok {
+			c = &Contributor{UserName: name, UserProfileUrl: profileUrl}
+			counts[name] = c
+			order = append(order, name)
+		} else if c.UserProfileUrl == "" {
+			c.UserProfileUrl = profileUrl
+		}
+		c.MergeCount++
+	}
+
+	fetcher, canFetchCoAuthors := forge.(CoAuthorFetcher)
+
+	for _, m := range merges {
+		add(m.UserName, m.UserProfileUrl)
+
+		if !canFetchCoAuthors {
+			continue
+		}
+
+		coAuthors, err := fetcher.FetchCoAuthors(m.MergeNum)
+		if err != nil {
+			return nil, fmt.Errorf("can't fetch co-authors for #%d: %w", m.MergeNum, err)
+		}
+		// A co-author's trailer can repeat across several commits of the
+		// same PR; dedupe per PR so they're only counted once for it.
+		seen := map[string]bool{}
+		for _, name := range coAuthors {
+			if !seen[name] {
+				seen[name] = true
+				add(name, "")
+			}
+		}
+	}
+
+	contributors := make([]Contributor, 0, len(order))
+	for _, name := range order {
+		contributors = append(contributors, *counts[name])
+	}
+
+	sort.SliceStable(contributors, func(i, j int) bool {
+		return contributors[i].MergeCount > contributors[j].MergeCount
+	})
+
+	return contributors, nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+)
+
+type (
+	MergeInfo struct {
+		CommitHash     string
+		UserName       string
+		UserProfileUrl string
+		//UserEmail      string
+		MergeNum int
+		MergeUrl string
+		Title    string
+		Labels   []string
+	}
+
+	// Forge abstracts over the hosting platform (GitHub, GitLab, Gitea, Gerrit, ...)
+	// that a repo's merges/changes are fetched from.
+	Forge interface {
+		// Name is the value matched against -forge (e.g. "github", "gitlab").
+		Name() string
+
+		// DetectFromRemote checks whether remoteURL belongs to this forge and, if so,
+		// returns a Forge instance configured to talk to it.
+		DetectFromRemote(remoteURL string) (Forge, bool)
+
+		// FetchMerges returns the merged PRs/MRs/changes since sinceTag.
+		FetchMerges(sinceTag string) ([]MergeInfo, error)
+	}
+)
+
+// forges lists every known Forge in detection order. Self-hosted forges that
+// can't be told apart by host alone (Gitea, Gerrit) only match when selected
+// explicitly via -forge.
+var forges = []Forge{
+	&GitHubForge{},
+	&GitLabForge{},
+	&GiteaForge{},
+	&GerritForge{},
+}
+
+func detectForge(remoteURL string) (Forge, error) {
+	for _, f := range forges {
+		if argv.Forge != "" && argv.Forge != "auto" && f.Name() != argv.Forge {
+			continue
+		}
+		if detected, ok := f.DetectFromRemote(remoteURL); ok {
+			return detected, nil
+		}
+	}
+	return nil, fmt.Errorf("can't detect forge for remote %q (try -forge)", remoteURL)
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// gerritMagicPrefix is prepended by Gerrit's REST API to every JSON response
+// body to guard against cross-site script inclusion; it must be stripped
+// before the body can be unmarshalled.
+const gerritMagicPrefix = ")]}'"
+
+// GerritForge talks to a self-hosted Gerrit instance. Like Gitea, it only
+// matches when explicitly selected via -forge=gerrit.
+type GerritForge struct {
+	baseUrl string
+	token   string
+}
+
+func (f *GerritForge) Name() string { return "gerrit" }
+
+func (f *GerritForge) DetectFromRemote(remoteURL string) (Forge, bool) {
+	if argv.Forge != "gerrit" {
+		return nil, false
+	}
+
+	parsed, err := url.Parse(remoteURL)
+	if err != nil {
+		return nil, false
+	}
+
+	return &GerritForge{
+		baseUrl: "https://" + parsed.Host + "/",
+		token:   argv.GitHubToken,
+	}, true
+}
+
+func (f *GerritForge) gerritAPI(p string, dst interface{}) error {
+	req, err := http.NewRequest("GET", f.baseUrl+p, nil)
+	if err != nil {
+		return fmt.Errorf(`can't create http request: %w`, err)
+	}
+
+	if f.token != "" {
+		req.Header.Add("Authorization", "Bearer "+f.token)
+	}
+
+	status, _, body, err := doCachedRequest(req)
+	if err != nil {
+		return err
+	}
+	respRaw := strings.TrimPrefix(string(body), gerritMagicPrefix)
+	if err := checkHTTPStatus(status, []byte(respRaw)); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal([]byte(respRaw), dst); err != nil {
+		return fmt.Errorf("can't parse response %q: %w", respRaw, err)
+	}
+
+	return nil
+}
+
+// gerritTimestampLayout is the fixed-width timestamp format Gerrit's REST API
+// uses for fields like "updated" (UTC, no timezone suffix).
+const gerritTimestampLayout = "2006-01-02 15:04:05.000000000"
+
+const gerritPageSize = 100
+
+func (f *GerritForge) FetchMerges(sinceTag string) ([]MergeInfo, error) {
+	mergeCommits, cutoff, err := scopeFilter(sinceTag)
+	if err != nil {
+		return nil, err
+	}
+
+	type change struct {
+		Number  int `json:"_number"`
+		Subject string
+		Owner   struct {
+			Name     string
+			Username string
+		}
+		CurrentRevision string   `json:"current_revision"`
+		Hashtags        []string `json:"hashtags"`
+		Updated         string   `json:"updated"`
+		MoreChanges     bool     `json:"_more_changes"`
+	}
+
+	var merges []MergeInfo
+
+	// Gerrit returns changes newest-updated-first by default; S is the
+	// result offset, and the last entry of a page sets _more_changes when
+	// another page follows.
+	start := 0
+	for {
+		var changes []change
+		// DETAILED_ACCOUNTS is needed too: without it, Owner only carries
+		// an opaque _account_id and Name/Username both come back empty.
+		page := fmt.Sprintf("changes/?q=status:merged&o=CURRENT_REVISION&o=DETAILED_ACCOUNTS&n=%d&S=%d", gerritPageSize, start)
+		if err := f.gerritAPI(page, &changes); err != nil {
+			return nil, fmt.Errorf("get changes from gerritAPI: %w", err)
+		}
+
+		stop := len(changes) == 0
+		for _, c := range changes {
+			if !cutoff.IsZero() {
+				if updated, err := time.Parse(gerritTimestampLayout, c.Updated); err == nil && updated.Before(cutoff) {
+					stop = true
+					break
+				}
+			}
+
+			// current_revision is whatever Gerrit actually landed on submit
+			// (including the rewritten commit for Rebase/Cherry-Pick/Fast-
+			// Forward strategies), so matching it against scopeFilter's
+			// rev-list-reachable set (rather than a merge-commits-only set)
+			// is sound even for those non-merge submit strategies.
+			if mergeCommits != nil && !mergeCommits[c.CurrentRevision] {
+				continue
+			}
+
+			merges = append(merges, MergeInfo{
+				CommitHash:     c.CurrentRevision,
+				UserName:       c.Owner.Username,
+				UserProfileUrl: f.baseUrl + "q/owner:" + c.Owner.Username,
+				MergeNum:       c.Number,
+				MergeUrl:       f.baseUrl + "c/" + strconv.Itoa(c.Number),
+				Title:          title(c.Subject),
+				Labels:         c.Hashtags,
+			})
+		}
+
+		if stop || !changes[len(changes)-1].MoreChanges {
+			break
+		}
+		start += gerritPageSize
+	}
+
+	return merges, nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// GiteaForge talks to a self-hosted Gitea instance. Unlike github.com/gitlab.com,
+// Gitea hosts can be anything, so it never auto-detects from the remote host and
+// only matches when explicitly selected via -forge=gitea.
+type GiteaForge struct {
+	apiUrl string
+	token  string
+}
+
+func (f *GiteaForge) Name() string { return "gitea" }
+
+func (f *GiteaForge) DetectFromRemote(remoteURL string) (Forge, bool) {
+	if argv.Forge != "gitea" {
+		return nil, false
+	}
+
+	parsed, err := url.Parse(remoteURL)
+	if err != nil {
+		return nil, false
+	}
+
+	orgWithProject := strings.TrimPrefix(strings.TrimSuffix(parsed.Path, ".git"), "/")
+
+	return &GiteaForge{
+		apiUrl: "https://" + parsed.Host + "/api/v1/repos/" + orgWithProject + "/",
+		token:  argv.GitHubToken,
+	}, true
+}
+
+// giteaAPI fetches one page. p may be either a path relative to f.apiUrl
+// (the first page) or a full URL taken from a previous page's Link header
+// (Gitea follows the same GitHub-style pagination convention).
+func (f *GiteaForge) giteaAPI(p string, dst interface{}) (nextPage string, err error) {
+	reqUrl := p
+	if !strings.HasPrefix(p, "http://") && !strings.HasPrefix(p, "https://") {
+		reqUrl = f.apiUrl + p
+	}
+
+	req, err := http.NewRequest("GET", reqUrl, nil)
+	if err != nil {
+		return "", fmt.Errorf(`can't create http request: %w`, err)
+	}
+
+	if f.token != "" {
+		req.Header.Add("Authorization", "token "+f.token)
+	}
+
+	status, header, body, err := doCachedRequest(req)
+	if err != nil {
+		return "", err
+	}
+	if err := checkHTTPStatus(status, body); err != nil {
+		return "", err
+	}
+
+	if err := json.Unmarshal(body, dst); err != nil {
+		return "", fmt.Errorf("can't parse response %q: %w", body, err)
+	}
+
+	return nextLinkURL(header), nil
+}
+
+func (f *GiteaForge) FetchMerges(sinceTag string) ([]MergeInfo, error) {
+	mergeCommits, cutoff, err := scopeFilter(sinceTag)
+	if err != nil {
+		return nil, err
+	}
+
+	type pull struct {
+		Url    string `json:"html_url"`
+		Number int
+		Title  string
+		User   struct {
+			Login   string
+			HtmlUrl string `json:"html_url"`
+		}
+		MergeCommitSHA string `json:"merge_commit_sha"`
+		MergedAt       string `json:"merged_at"`
+		UpdatedAt      string `json:"updated_at"`
+		Labels         []struct {
+			Name string
+		}
+	}
+
+	var merges []MergeInfo
+
+	// recentupdate keeps updated_at monotonically decreasing across pages,
+	// the same early-exit precondition used for GitHub/GitLab.
+	page := "pulls?state=closed&sort=recentupdate&limit=50"
+fetchPages:
+	for page != "" {
+		var pullsInfo []pull
+		nextPage, err := f.giteaAPI(page, &pullsInfo)
+		if err != nil {
+			return nil, fmt.Errorf("get pulls from giteaAPI: %w", err)
+		}
+
+		for _, pi := range pullsInfo {
+			if pi.MergedAt == "" {
+				continue
+			}
+
+			if !cutoff.IsZero() {
+				updatedAt, err := time.Parse(time.RFC3339, pi.UpdatedAt)
+				if err == nil && updatedAt.Before(cutoff) {
+					break fetchPages
+				}
+			}
+
+			if mergeCommits != nil && !mergeCommits[pi.MergeCommitSHA] {
+				continue
+			}
+
+			labels := make([]string, 0, len(pi.Labels))
+			for _, l := range pi.Labels {
+				labels = append(labels, l.Name)
+			}
+
+			merges = append(merges, MergeInfo{
+				CommitHash:     pi.MergeCommitSHA,
+				UserName:       pi.User.Login,
+				UserProfileUrl: pi.User.HtmlUrl,
+				MergeNum:       pi.Number,
+				MergeUrl:       pi.Url,
+				Title:          title(pi.Title),
+				Labels:         labels,
+			})
+		}
+
+		page = nextPage
+	}
+
+	return merges, nil
+}
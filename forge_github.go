@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type GitHubForge struct {
+	apiUrl string
+	token  string
+}
+
+func (f *GitHubForge) Name() string { return "github" }
+
+func (f *GitHubForge) DetectFromRemote(remoteURL string) (Forge, bool) {
+	parsed, err := url.Parse(remoteURL)
+	if err != nil || parsed.Host != "github.com" {
+		return nil, false
+	}
+
+	orgWithProject := strings.TrimSuffix(parsed.Path, ".git")
+
+	return &GitHubForge{
+		apiUrl: "https://api.github.com/repos" + orgWithProject + "/",
+		token:  argv.GitHubToken,
+	}, true
+}
+
+// githubAPI fetches one page. p may be either a path relative to f.apiUrl
+// (the first page) or a full URL taken from a previous page's Link header.
+// It returns the "next" page URL from that header, or "" once exhausted.
+func (f *GitHubForge) githubAPI(p string, dst interface{}) (nextPage string, err error) {
+	reqUrl := p
+	if !strings.HasPrefix(p, "http://") && !strings.HasPrefix(p, "https://") {
+		reqUrl = f.apiUrl + p
+	}
+
+	req, err := http.NewRequest("GET", reqUrl, nil)
+	if err != nil {
+		return "", fmt.Errorf(`can't create http request: %w`, err)
+	}
+
+	req.Header.Add("Accept", "application/vnd.github.v3+json")
+	if f.token != "" {
+		req.Header.Add("Authorization", "token "+f.token)
+	}
+
+	status, header, body, err := doCachedRequest(req)
+	if err != nil {
+		return "", err
+	}
+	if err := checkHTTPStatus(status, body); err != nil {
+		return "", err
+	}
+
+	if err := json.Unmarshal(body, dst); err != nil {
+		return "", fmt.Errorf("can't parse response %q: %w", body, err)
+	}
+
+	return nextLinkURL(header), nil
+}
+
+// FetchCoAuthors implements CoAuthorFetcher by scanning a PR's commits for
+// "Co-authored-by:" trailers.
+func (f *GitHubForge) FetchCoAuthors(mergeNum int) ([]string, error) {
+	type commit struct {
+		Commit struct {
+			Message string
+		}
+	}
+
+	var names []string
+
+	page := fmt.Sprintf("pulls/%d/commits?per_page=100", mergeNum)
+	for page != "" {
+		var commits []commit
+		nextPage, err := f.githubAPI(page, &commits)
+		if err != nil {
+			return nil, fmt.Errorf("get commits from githubAPI: %w", err)
+		}
+
+		for _, c := range commits {
+			names = append(names, parseCoAuthorTrailers(c.Commit.Message)...)
+		}
+
+		page = nextPage
+	}
+
+	return names, nil
+}
+
+var linkHeaderRegex = regexp.MustCompile(`<([^>]+)>;\s*rel="([^"]+)"`)
+
+// nextLinkURL extracts the rel="next" target from a GitHub-style paginated
+// Link header, e.g. `<...&page=2>; rel="next", <...>; rel="last"`.
+func nextLinkURL(header http.Header) string {
+	for _, m := range linkHeaderRegex.FindAllStringSubmatch(header.Get("Link"), -1) {
+		if m[2] == "next" {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+func (f *GitHubForge) FetchMerges(sinceTag string) ([]MergeInfo, error) {
+	mergeCommits, cutoff, err := scopeFilter(sinceTag)
+	if err != nil {
+		return nil, err
+	}
+
+	type pull struct {
+		Url    string `json:"html_url"`
+		Number int
+		Title  string
+		User   struct {
+			Login string
+			Url   string `json:"html_url"`
+		}
+		MergeCommitSHA string `json:"merge_commit_sha"`
+		MergedAt       string `json:"merged_at"`
+		UpdatedAt      string `json:"updated_at"`
+		Labels         []struct {
+			Name string
+		}
+		// Body string
+	}
+
+	var merges []MergeInfo
+
+	// Sorted by updated (not created) so that updated_at is monotonically
+	// decreasing across pages: since updated_at >= merged_at always, the
+	// first page whose updated_at falls before cutoff guarantees every PR
+	// from there on merged before cutoff too, making the early exit sound.
+	page := "pulls?state=closed&sort=updated&direction=desc&per_page=100"
+fetchPages:
+	for page != "" {
+		var pullsInfo []pull
+		nextPage, err := f.githubAPI(page, &pullsInfo)
+		if err != nil {
+			return nil, fmt.Errorf("get pulls from githubAPI: %w", err)
+		}
+
+		for _, pi := range pullsInfo {
+			if pi.MergedAt == "" {
+				continue
+			}
+
+			if !cutoff.IsZero() {
+				updatedAt, err := time.Parse(time.RFC3339, pi.UpdatedAt)
+				if err == nil && updatedAt.Before(cutoff) {
+					break fetchPages
+				}
+			}
+
+			if mergeCommits != nil && !mergeCommits[pi.MergeCommitSHA] {
+				continue
+			}
+
+			labels := make([]string, 0, len(pi.Labels))
+			for _, l := range pi.Labels {
+				labels = append(labels, l.Name)
+			}
+
+			merges = append(merges, MergeInfo{
+				CommitHash:     pi.MergeCommitSHA,
+				UserName:       pi.User.Login,
+				UserProfileUrl: pi.User.Url,
+				MergeNum:       pi.Number,
+				MergeUrl:       pi.Url,
+				Title:          title(pi.Title),
+				Labels:         labels,
+			})
+		}
+
+		page = nextPage
+	}
+
+	return merges, nil
+}
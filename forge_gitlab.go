@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type GitLabForge struct {
+	apiUrl string
+	token  string
+}
+
+func (f *GitLabForge) Name() string { return "gitlab" }
+
+func (f *GitLabForge) DetectFromRemote(remoteURL string) (Forge, bool) {
+	parsed, err := url.Parse(remoteURL)
+	if err != nil || parsed.Host != "gitlab.com" {
+		return nil, false
+	}
+
+	projectPath := strings.TrimPrefix(strings.TrimSuffix(parsed.Path, ".git"), "/")
+
+	return &GitLabForge{
+		apiUrl: "https://gitlab.com/api/v4/projects/" + url.PathEscape(projectPath) + "/",
+		token:  argv.GitHubToken,
+	}, true
+}
+
+// gitlabAPI fetches one page. p may be either a path relative to f.apiUrl
+// (the first page) or a full URL taken from a previous page's Link header.
+// It returns the "next" page URL from that header, or "" once exhausted.
+func (f *GitLabForge) gitlabAPI(p string, dst interface{}) (nextPage string, err error) {
+	reqUrl := p
+	if !strings.HasPrefix(p, "http://") && !strings.HasPrefix(p, "https://") {
+		reqUrl = f.apiUrl + p
+	}
+
+	req, err := http.NewRequest("GET", reqUrl, nil)
+	if err != nil {
+		return "", fmt.Errorf(`can't create http request: %w`, err)
+	}
+
+	if f.token != "" {
+		req.Header.Add("PRIVATE-TOKEN", f.token)
+	}
+
+	status, header, body, err := doCachedRequest(req)
+	if err != nil {
+		return "", err
+	}
+	if err := checkHTTPStatus(status, body); err != nil {
+		return "", err
+	}
+
+	if err := json.Unmarshal(body, dst); err != nil {
+		return "", fmt.Errorf("can't parse response %q: %w", body, err)
+	}
+
+	return nextLinkURL(header), nil
+}
+
+func (f *GitLabForge) FetchMerges(sinceTag string) ([]MergeInfo, error) {
+	mergeCommits, cutoff, err := scopeFilter(sinceTag)
+	if err != nil {
+		return nil, err
+	}
+
+	type mr struct {
+		Url    string `json:"web_url"`
+		Iid    int
+		Title  string
+		Author struct {
+			Username string
+			WebUrl   string `json:"web_url"`
+		}
+		MergeCommitSHA string   `json:"merge_commit_sha"`
+		MergedAt       string   `json:"merged_at"`
+		UpdatedAt      string   `json:"updated_at"`
+		Labels         []string `json:"labels"`
+	}
+
+	var merges []MergeInfo
+
+	// Sorted by updated so that, like GitHub, updated_at is monotonically
+	// decreasing across pages and safe to gate the early exit on.
+	page := "merge_requests?state=merged&order_by=updated_at&sort=desc&per_page=100"
+fetchPages:
+	for page != "" {
+		var mrsInfo []mr
+		nextPage, err := f.gitlabAPI(page, &mrsInfo)
+		if err != nil {
+			return nil, fmt.Errorf("get merge requests from gitlabAPI: %w", err)
+		}
+
+		for _, mi := range mrsInfo {
+			if mi.MergedAt == "" {
+				continue
+			}
+
+			if !cutoff.IsZero() {
+				updatedAt, err := time.Parse(time.RFC3339, mi.UpdatedAt)
+				if err == nil && updatedAt.Before(cutoff) {
+					break fetchPages
+				}
+			}
+
+			if mergeCommits != nil && !mergeCommits[mi.MergeCommitSHA] {
+				continue
+			}
+
+			merges = append(merges, MergeInfo{
+				CommitHash:     mi.MergeCommitSHA,
+				UserName:       mi.Author.Username,
+				UserProfileUrl: mi.Author.WebUrl,
+				MergeNum:       mi.Iid,
+				MergeUrl:       mi.Url,
+				Title:          title(mi.Title),
+				Labels:         mi.Labels,
+			})
+		}
+
+		page = nextPage
+	}
+
+	return merges, nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// cacheDir holds one file per cached response, named after a hash of the
+// request it answers.
+const cacheDir = ".changeloger-cache"
+
+type cacheEntry struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	StoredAt   time.Time
+}
+
+func cacheKey(req *http.Request) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\nAccept: %s\nAuthorization: %s",
+		req.Method, req.URL.String(), req.Header.Get("Accept"), req.Header.Get("Authorization"))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func cachePath(req *http.Request) string {
+	return filepath.Join(cacheDir, cacheKey(req)+".json")
+}
+
+func loadCacheEntry(req *http.Request) *cacheEntry {
+	data, err := os.ReadFile(cachePath(req))
+	if err != nil {
+		return nil
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil
+	}
+	return &entry
+}
+
+func saveCacheEntry(req *http.Request, entry *cacheEntry) error {
+	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+		return fmt.Errorf("can't create cache dir: %w", err)
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("can't marshal cache entry: %w", err)
+	}
+
+	return os.WriteFile(cachePath(req), data, 0o644)
+}
+
+func rawRequest(req *http.Request) (int, http.Header, []byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("can't get response: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("can't read response: %w", err)
+	}
+
+	return resp.StatusCode, resp.Header, body, nil
+}
+
+// doCachedRequest runs req through the on-disk response cache: a cache entry
+// younger than -cache-ttl is returned without touching the network at all; an
+// older one is revalidated with If-None-Match/If-Modified-Since, and a 304
+// response just refreshes the cached copy in place. -no-cache bypasses the
+// cache entirely in both directions.
+func doCachedRequest(req *http.Request) (int, http.Header, []byte, error) {
+	if argv.NoCache {
+		return rawRequest(req)
+	}
+
+	entry := loadCacheEntry(req)
+	if entry != nil {
+		if argv.CacheTTL > 0 && time.Since(entry.StoredAt) < argv.CacheTTL {
+			return entry.StatusCode, entry.Header, entry.Body, nil
+		}
+		if etag := entry.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastMod := entry.Header.Get("Last-Modified"); lastMod != "" {
+			req.Header.Set("If-Modified-Since", lastMod)
+		}
+	}
+
+	status, header, body, err := rawRequest(req)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	if status == http.StatusNotModified && entry != nil {
+		entry.StoredAt = time.Now()
+		if err := saveCacheEntry(req, entry); err != nil {
+			log.Println("can't refresh cache entry:", err)
+		}
+		return entry.StatusCode, entry.Header, entry.Body, nil
+	}
+
+	// Only 2xx responses are worth caching: a rate-limit or server error
+	// body cached for -cache-ttl would otherwise get re-served as if it
+	// were a good response on every run until it expires.
+	if status >= 200 && status < 300 {
+		newEntry := &cacheEntry{StatusCode: status, Header: header, Body: body, StoredAt: time.Now()}
+		if err := saveCacheEntry(req, newEntry); err != nil {
+			log.Println("can't save cache entry:", err)
+		}
+	}
+
+	return status, header, body, nil
+}
+
+// checkHTTPStatus turns a non-2xx forge API response into an error instead
+// of letting callers unmarshal an error body as if it were the real payload.
+func checkHTTPStatus(status int, body []byte) error {
+	if status < 200 || status >= 300 {
+		return fmt.Errorf("unexpected HTTP status %d: %s", status, body)
+	}
+	return nil
+}
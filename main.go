@@ -3,14 +3,12 @@ package main
 import (
 	"bufio"
 	"bytes"
-	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
-	"net/url"
 	"os/exec"
 	"regexp"
 	"strings"
@@ -25,12 +23,17 @@ import (
 var (
 	client = http.Client{}
 
-	apiUrl string
-
 	argv struct {
-		GitHubToken string
-		Help        bool
-		MainBranch  string
+		GitHubToken  string
+		Help         bool
+		MainBranch   string
+		Forge        string
+		NoCache      bool
+		CacheTTL     time.Duration
+		LabelsConfig string
+		Bump         string
+		Write        string
+		CreateTag    bool
 	}
 )
 
@@ -43,55 +46,24 @@ var gitUrlRegex = regexp.MustCompile("^git@([^:]+):([^/]+)/([^/]+).git$")
 func init() {
 	flag.StringVar(&argv.GitHubToken, "github-token", "", "OAuth2 token for GitHub API")
 	flag.StringVar(&argv.MainBranch, "main-branch", "main", "Name of the main branch (main, master, ...)")
+	flag.StringVar(&argv.Forge, "forge", "auto", "Forge backend to use (auto, github, gitlab, gitea, gerrit)")
+	flag.BoolVar(&argv.NoCache, "no-cache", false, "Bypass the on-disk HTTP response cache")
+	flag.DurationVar(&argv.CacheTTL, "cache-ttl", time.Hour, "How long a cached response is reused before revalidation")
+	flag.StringVar(&argv.LabelsConfig, "labels-config", "", "YAML file mapping forge label names to changelog categories, used as a fallback when a title isn't Conventional Commits")
+	flag.StringVar(&argv.Bump, "bump", "", "Compute the new tag instead of leaving "+NewTagPlaceholder+" in place (auto, major, minor, patch)")
+	flag.StringVar(&argv.Write, "write", "", "Splice the new section into this CHANGELOG.md instead of printing it to stdout")
+	flag.BoolVar(&argv.CreateTag, "create-tag", false, "Create the computed tag with git after a successful -write (requires -bump)")
 	flag.BoolVar(&argv.Help, "help", false, "Show this help")
 	flag.BoolVar(&argv.Help, "h", false, "Show this help")
 
 	flag.Parse()
 }
 
-type (
-	MergeInfo struct {
-		CommitHash     string
-		UserName       string
-		UserProfileUrl string
-		//UserEmail      string
-		MergeNum int
-		MergeUrl string
-		Title    string
-	}
-)
-
-func githubAPI(p string, dst interface{}) error {
-	req, err := http.NewRequest("GET", apiUrl+p, nil)
-	if err != nil {
-		return fmt.Errorf(`can't create http request: %w`, err)
-	}
-
-	req.Header.Add("Accept", "application/vnd.github.v3+json")
-	if argv.GitHubToken != "" {
-		req.Header.Add("Authorization", "token "+argv.GitHubToken)
-	}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("can't get response: %w", err)
-	}
-
-	var buf bytes.Buffer
-	_, err = buf.ReadFrom(resp.Body)
-	resp.Body.Close()
-
-	if err != nil {
-		return fmt.Errorf("can't read response: %w", err)
-	}
-	respRaw := buf.String()
-
-	err = json.NewDecoder(&buf).Decode(dst)
-	if err != nil {
-		return fmt.Errorf("can't parse response %q: %w", respRaw, err)
+func normalizeRemoteURL(origin string) string {
+	if m := gitUrlRegex.FindAllStringSubmatch(origin, -1); len(m) > 0 {
+		origin = "git://" + strings.Join(m[0][1:4], "/")
 	}
-
-	return nil
+	return origin
 }
 
 func execGit(dst io.Writer, args ...string) error {
@@ -120,38 +92,6 @@ func execGitOneLine(args ...string) (string, error) {
 	return strings.TrimSpace(buf.String()), nil
 }
 
-func setupRepoAPIURL() error {
-	origin, err := execGitOneLine("config", "--get", "remote.origin.url")
-	if err != nil {
-		return err
-	}
-
-	if m := gitUrlRegex.FindAllStringSubmatch(origin, -1); len(m) > 0 {
-		origin = "git://" + strings.Join(m[0][1:4], "/")
-	}
-
-	parsed, err := url.Parse(origin)
-	if err != nil {
-		return fmt.Errorf("url parse: %w", err)
-	}
-
-	if parsed.Host != "github.com" {
-		return fmt.Errorf("only github.com repos are supported")
-	}
-
-	orgWithProject := strings.TrimSuffix(parsed.Path, ".git")
-	if matched, err := regexp.MatchString("", orgWithProject); err != nil {
-		return err
-	} else if !matched {
-		return fmt.Errorf("wrong repo name")
-	}
-
-	apiUrl = "https://api.github.com/repos" + orgWithProject + "/"
-	log.Println("apiUrl:", apiUrl)
-
-	return nil
-}
-
 func getLastGitTag() (string, error) {
 	var buf bytes.Buffer
 	if err := execGit(&buf, "tag"); err != nil {
@@ -182,43 +122,73 @@ func getLastGitTag() (string, error) {
 	return maxVerAsIs, nil
 }
 
-func getGitMerges(lastTag string) ([]MergeInfo, error) {
-	var pullsInfo []struct {
-		Url    string `json:"html_url"`
-		Number int
-		State  string
-		Title  string
-		User   struct {
-			Login string
-			Url   string `json:"html_url"`
+// commitsSince returns the set of every commit hash reachable from HEAD but
+// not from tag. An empty tag means "no tag yet", in which case nil is
+// returned so callers skip the filter entirely.
+//
+// This intentionally uses rev-list rather than `log --merges`: a squash- or
+// rebase-merged PR lands as a single-parent commit that --merges would never
+// list, and a Gerrit change submitted with the Rebase/Cherry-Pick/Fast-Forward
+// strategy rewrites the commit on submit, so the landed SHA is whatever
+// actually ended up in history, not necessarily a merge commit. rev-list
+// reachability covers all of those the same way, since it's checking "is
+// this SHA in history since tag" rather than "is this SHA a merge".
+func commitsSince(tag string) (map[string]bool, error) {
+	if tag == "" {
+		return nil, nil
+	}
+
+	var buf bytes.Buffer
+	if err := execGit(&buf, "rev-list", tag+"..HEAD"); err != nil {
+		return nil, fmt.Errorf("can't list commits: %w", err)
+	}
+
+	commits := make(map[string]bool)
+	rdr := bufio.NewScanner(&buf)
+	for rdr.Scan() {
+		if sha := strings.TrimSpace(rdr.Text()); sha != "" {
+			commits[sha] = true
 		}
-		MergeCommitSHA string `json:"merge_commit_sha"`
-		MergedAt       string `json:"merged_at"`
-		// Body string
 	}
+	if rdr.Err() != nil {
+		return nil, fmt.Errorf("can't parse commit list: %w", rdr.Err())
+	}
+
+	return commits, nil
+}
 
-	err := githubAPI("pulls?state=closed&sort=updated&direction=desc&per_page=100", &pullsInfo)
+func tagCommitDate(tag string) (time.Time, error) {
+	s, err := execGitOneLine("log", "-1", "--format=%aI", tag)
 	if err != nil {
-		return nil, fmt.Errorf("get pulls from githubAPI: %w", err)
+		return time.Time{}, fmt.Errorf("can't get tag commit date: %w", err)
 	}
 
-	var merges []MergeInfo
-	for _, pi := range pullsInfo {
-		if pi.MergedAt == "" {
-			continue
-		}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("can't parse tag commit date %q: %w", s, err)
+	}
+
+	return t, nil
+}
+
+// scopeFilter computes what a Forge needs to restrict FetchMerges to merges
+// since sinceTag: the authoritative set of commit hashes in scope, and a
+// cutoff date a forge can use to stop paginating early. An empty sinceTag
+// means "no tag yet" and yields a nil set and a zero cutoff, i.e. no filter.
+func scopeFilter(sinceTag string) (commits map[string]bool, cutoff time.Time, err error) {
+	commits, err = commitsSince(sinceTag)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("can't list commits since %q: %w", sinceTag, err)
+	}
 
-		merges = append(merges, MergeInfo{
-			CommitHash:     pi.MergeCommitSHA,
-			UserName:       pi.User.Login,
-			UserProfileUrl: pi.User.Url,
-			MergeNum:       pi.Number,
-			MergeUrl:       pi.Url,
-			Title:          title(pi.Title),
-		})
+	if sinceTag != "" {
+		cutoff, err = tagCommitDate(sinceTag)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("can't get date of tag %q: %w", sinceTag, err)
+		}
 	}
 
-	return merges, nil
+	return commits, cutoff, nil
 }
 
 func title(s string) string {
@@ -237,11 +207,44 @@ func title(s string) string {
 	return string(unicode.ToTitle(r)) + s[size:]
 }
 
-func generateChangelogSection(merges []MergeInfo) (string, error) {
+type changelogSection struct {
+	Title  string
+	Merges []MergeInfo
+}
+
+// groupByCategory buckets merges into changelogSections ordered by
+// categoryOrder, dropping empty categories.
+func groupByCategory(merges []MergeInfo, labelCategories map[string]string) []changelogSection {
+	grouped := make(map[string][]MergeInfo)
+	for _, m := range merges {
+		cat := categorize(m, labelCategories)
+		grouped[cat] = append(grouped[cat], m)
+	}
+
+	var sections []changelogSection
+	for _, cat := range categoryOrder {
+		if ms := grouped[cat]; len(ms) > 0 {
+			sections = append(sections, changelogSection{Title: categoryTitles[cat], Merges: ms})
+		}
+	}
+
+	return sections
+}
+
+func generateChangelogSection(merges []MergeInfo, labelCategories map[string]string, tag string, contributors []Contributor) (string, error) {
 	const tpl = `
 ### Tag {{.Tag}} ({{.Date}})
+{{range .Sections}}
+### {{.Title}}
 {{range .Merges -}}
 * {{.Title}}. [#{{.MergeNum}}]({{.MergeUrl}}) ([{{.UserName}}]({{.UserProfileUrl}}))
+{{end}}
+{{end -}}
+{{if .Contributors}}
+### Contributors
+{{range .Contributors -}}
+* {{.Markdown}} ({{.MergeCount}})
+{{end}}
 {{end -}}
 `
 
@@ -251,13 +254,15 @@ func generateChangelogSection(merges []MergeInfo) (string, error) {
 	}
 
 	fields := struct {
-		Tag    string
-		Date   string
-		Merges []MergeInfo
+		Tag          string
+		Date         string
+		Sections     []changelogSection
+		Contributors []Contributor
 	}{
-		Tag:    NewTagPlaceholder,
-		Date:   time.Now().Format("2006-01-02"),
-		Merges: merges,
+		Tag:          tag,
+		Date:         time.Now().Format("2006-01-02"),
+		Sections:     groupByCategory(merges, labelCategories),
+		Contributors: contributors,
 	}
 
 	var buf bytes.Buffer
@@ -269,29 +274,56 @@ func generateChangelogSection(merges []MergeInfo) (string, error) {
 	return buf.String(), nil
 }
 
-func generate() (string, error) {
-	err := setupRepoAPIURL()
+func generate() (changelog string, tag string, err error) {
+	origin, err := execGitOneLine("config", "--get", "remote.origin.url")
+	if err != nil {
+		return "", "", fmt.Errorf("can't get repo remote url: %w", err)
+	}
+	origin = normalizeRemoteURL(origin)
+
+	forge, err := detectForge(origin)
 	if err != nil {
-		return "", fmt.Errorf("can't get repo url: %w", err)
+		return "", "", fmt.Errorf("can't detect forge: %w", err)
 	}
 
-	//lastTag, err := getLastGitTag()
-	//if err != nil {
-	//	return "", fmt.Errorf("can't get last repo tag: %w", err)
-	//}
-	lastTag := "unknown"
+	lastTag, err := getLastGitTag()
+	if err != nil {
+		return "", "", fmt.Errorf("can't get last repo tag: %w", err)
+	}
 
-	merges, err := getGitMerges(lastTag)
+	merges, err := forge.FetchMerges(lastTag)
 	if err != nil {
-		return "", fmt.Errorf("can't get merges from last tag %q: %w", lastTag, err)
+		return "", "", fmt.Errorf("can't get merges from last tag %q: %w", lastTag, err)
+	}
+	if len(merges) == 0 && lastTag != "" {
+		log.Printf("No merges found since tag %q; the changelog will be empty. "+
+			"Double check -forge and that %q is actually the right tag to diff against.", lastTag, lastTag)
 	}
 
-	cl, err := generateChangelogSection(merges)
+	labelCategories, err := loadLabelsConfig(argv.LabelsConfig)
 	if err != nil {
-		return "", fmt.Errorf("can't generate changelog: %w", err)
+		return "", "", fmt.Errorf("can't load labels config: %w", err)
+	}
+
+	tag = NewTagPlaceholder
+	if argv.Bump != "" {
+		tag, err = nextVersion(lastTag, merges, labelCategories)
+		if err != nil {
+			return "", "", fmt.Errorf("can't compute next version: %w", err)
+		}
 	}
 
-	return cl, nil
+	contributors, err := collectContributors(forge, merges)
+	if err != nil {
+		return "", "", fmt.Errorf("can't collect contributors: %w", err)
+	}
+
+	cl, err := generateChangelogSection(merges, labelCategories, tag, contributors)
+	if err != nil {
+		return "", "", fmt.Errorf("can't generate changelog: %w", err)
+	}
+
+	return cl, tag, nil
 }
 
 func main() {
@@ -300,10 +332,25 @@ func main() {
 		return
 	}
 
-	changelog, err := generate()
+	changelog, tag, err := generate()
 	if err != nil {
 		log.Fatalln(err)
 	}
 
-	fmt.Println(changelog)
+	if argv.Write != "" {
+		if err := writeChangelog(argv.Write, changelog); err != nil {
+			log.Fatalln(err)
+		}
+	} else {
+		fmt.Println(changelog)
+	}
+
+	if argv.CreateTag {
+		if argv.Bump == "" {
+			log.Fatalln("-create-tag requires -bump")
+		}
+		if err := createGitTag(tag); err != nil {
+			log.Fatalln(err)
+		}
+	}
 }
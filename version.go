@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Masterminds/semver"
+)
+
+// computeBumpLevel inspects the categorized merges to decide the semver bump:
+// any breaking change forces major, any feature forces minor, otherwise patch.
+func computeBumpLevel(merges []MergeInfo, labelCategories map[string]string) string {
+	level := "patch"
+	for _, m := range merges {
+		switch categorize(m, labelCategories) {
+		case CategoryBreaking:
+			return "major"
+		case CategoryFeatures:
+			level = "minor"
+		}
+	}
+	return level
+}
+
+// nextVersion computes the tag for -bump, incrementing lastTag (or v0.0.0 if
+// there isn't one yet) by the level requested via -bump, resolving "auto" via
+// computeBumpLevel.
+func nextVersion(lastTag string, merges []MergeInfo, labelCategories map[string]string) (string, error) {
+	// Match whatever prefix convention lastTag already used; default to "v"
+	// when there's no tag yet to take the convention from.
+	prefix := "v"
+	if lastTag != "" && !strings.HasPrefix(lastTag, "v") {
+		prefix = ""
+	}
+
+	base, err := semver.NewVersion(lastTag)
+	if err != nil {
+		base = semver.MustParse("0.0.0")
+	}
+
+	level := argv.Bump
+	if level == "auto" {
+		level = computeBumpLevel(merges, labelCategories)
+	}
+
+	var next semver.Version
+	switch level {
+	case "major":
+		next = base.IncMajor()
+	case "minor":
+		next = base.IncMinor()
+	case "patch":
+		next = base.IncPatch()
+	default:
+		return "", fmt.Errorf("unknown -bump level %q", level)
+	}
+
+	return prefix + next.String(), nil
+}
+
+// writeChangelog splices section at the top of path, below a stable "# ..."
+// header line if one is present, creating the file with a default header if
+// it doesn't exist yet.
+func writeChangelog(path, section string) error {
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("can't read %s: %w", path, err)
+	}
+
+	header, rest := "# Changelog\n", ""
+	if err == nil {
+		content := string(existing)
+		if strings.HasPrefix(content, "# ") {
+			if idx := strings.Index(content, "\n"); idx >= 0 {
+				header, rest = content[:idx+1], content[idx+1:]
+			}
+		} else {
+			header, rest = "", content
+		}
+	}
+
+	if err := os.WriteFile(path, []byte(header+section+rest), 0o644); err != nil {
+		return fmt.Errorf("can't write %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func createGitTag(tag string) error {
+	if err := execGit(io.Discard, "tag", "-a", tag, "-m", "Release "+tag); err != nil {
+		return fmt.Errorf("can't create tag %q: %w", tag, err)
+	}
+	return nil
+}